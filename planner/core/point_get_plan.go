@@ -15,7 +15,9 @@ package core
 
 import (
 	"bytes"
+	"container/list"
 	"fmt"
+	"strconv"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/parser/ast"
@@ -30,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/types/parser_driver"
+	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -52,6 +55,15 @@ type PointGetPlan struct {
 	IsTableDual      bool
 	Lock             bool
 	IsForUpdate      bool
+	// PartitionInfo is the matched partition definition for a query against a
+	// partitioned table. It is nil for non-partitioned tables. The physical
+	// table ID used at execution time should come from PartitionInfo.ID rather
+	// than TblInfo.ID whenever it is set.
+	PartitionInfo *model.PartitionDefinition
+	// IndexOnly is true when IndexInfo is a unique secondary index whose
+	// columns cover every column in schema, so the row can be decoded directly
+	// from the index key/value without a second table-row fetch.
+	IndexOnly bool
 }
 
 type nameValuePair struct {
@@ -89,6 +101,9 @@ func (p *PointGetPlan) ExplainInfo() string {
 				buffer.WriteString(" ")
 			}
 		}
+		if p.IndexOnly {
+			fmt.Fprintf(buffer, ", index-only")
+		}
 	} else {
 		if p.UnsignedHandle {
 			fmt.Fprintf(buffer, ", handle:%d", uint64(p.Handle))
@@ -96,6 +111,9 @@ func (p *PointGetPlan) ExplainInfo() string {
 			fmt.Fprintf(buffer, ", handle:%d", p.Handle)
 		}
 	}
+	if p.PartitionInfo != nil {
+		fmt.Fprintf(buffer, ", partition:%s", p.PartitionInfo.Name.L)
+	}
 	if p.Lock {
 		fmt.Fprintf(buffer, ", lock")
 	}
@@ -137,6 +155,123 @@ func (p *PointGetPlan) ResolveIndices() error {
 	return nil
 }
 
+// batchPointGetExecSupported gates BatchPointGetPlan out of TryFastPlan's
+// live planning path until the executor side (attach2Task/ToPB and the
+// actual BatchGet RPC + result decoding) exists. Flip it once that lands.
+//
+// Status: that executor side does not exist anywhere in this tree, and
+// nothing in this series flips the flag. `WHERE col IN (...)` therefore
+// still plans exactly as it did before BatchPointGetPlan existed - one
+// PointGetPlan per entry under a PhysicalUnionAll - with no observable
+// behavior change. Treat BatchPointGetPlan and tryBuildBatchPointGetPlan as
+// unfinished planner-side scaffolding for that follow-up work, not as a
+// completed replacement of the UnionAll-of-PointGets path.
+const batchPointGetExecSupported = false
+
+// BatchPointGetPlan holds the handles or unique-index values of several
+// PointGetPlans built for a `SELECT ... WHERE col IN (...)` or
+// `WHERE (col1, col2) IN (...)` query whose entries all resolve to the same
+// handle column or the same unique index of the same physical table (the
+// same partition, for a partitioned table).
+// Privileges are already checked while building the individual PointGetPlans
+// this plan is merged from, so it does not re-check them.
+//
+// Its attach2Task and ToPB return nil, and TryFastPlan never produces one
+// while batchPointGetExecSupported is false.
+type BatchPointGetPlan struct {
+	basePlan
+	schema           *expression.Schema
+	TblInfo          *model.TableInfo
+	IndexInfo        *model.IndexInfo
+	PartitionInfo    *model.PartitionDefinition
+	Handles          []int64
+	HandleParams     []*driver.ParamMarkerExpr
+	IndexValues      [][]types.Datum
+	IndexValueParams [][]*driver.ParamMarkerExpr
+	ctx              sessionctx.Context
+	UnsignedHandle   bool
+	// IndexOnly mirrors PointGetPlan.IndexOnly: true when IndexInfo is a
+	// unique secondary index whose columns cover every column in schema, so
+	// each row can be decoded directly from the index key/value.
+	IndexOnly bool
+}
+
+// Schema implements the Plan interface.
+func (p *BatchPointGetPlan) Schema() *expression.Schema {
+	return p.schema
+}
+
+// attach2Task makes the current physical plan as the father of task's physicalPlan and updates the cost of
+// current task. If the child's task is cop task, some operator may close this task and return a new rootTask.
+func (p *BatchPointGetPlan) attach2Task(...task) task {
+	return nil
+}
+
+// ToPB converts physical plan to tipb executor.
+func (p *BatchPointGetPlan) ToPB(ctx sessionctx.Context) (*tipb.Executor, error) {
+	return nil, nil
+}
+
+// ExplainInfo returns operator information to be explained.
+func (p *BatchPointGetPlan) ExplainInfo() string {
+	buffer := bytes.NewBufferString("")
+	tblName := p.TblInfo.Name.O
+	fmt.Fprintf(buffer, "table:%s", tblName)
+	if p.IndexInfo != nil {
+		fmt.Fprintf(buffer, ", index:")
+		for i, col := range p.IndexInfo.Columns {
+			buffer.WriteString(col.Name.O)
+			if i < len(p.IndexInfo.Columns)-1 {
+				buffer.WriteString(" ")
+			}
+		}
+		if p.IndexOnly {
+			fmt.Fprintf(buffer, ", index-only")
+		}
+	} else {
+		fmt.Fprintf(buffer, ", handle:%d cases", len(p.Handles))
+	}
+	if p.PartitionInfo != nil {
+		fmt.Fprintf(buffer, ", partition:%s", p.PartitionInfo.Name.L)
+	}
+	return buffer.String()
+}
+
+// GetChildReqProps gets the required property by child index.
+func (p *BatchPointGetPlan) GetChildReqProps(idx int) *property.PhysicalProperty {
+	return nil
+}
+
+// StatsCount will return the the RowCount of property.StatsInfo for this plan.
+func (p *BatchPointGetPlan) StatsCount() float64 {
+	return float64(len(p.Handles) + len(p.IndexValues))
+}
+
+// statsInfo will return the the RowCount of property.StatsInfo for this plan.
+func (p *BatchPointGetPlan) statsInfo() *property.StatsInfo {
+	if p.stats == nil {
+		p.stats = &property.StatsInfo{}
+	}
+	p.stats.RowCount = p.StatsCount()
+	return p.stats
+}
+
+// Children gets all the children.
+func (p *BatchPointGetPlan) Children() []PhysicalPlan {
+	return nil
+}
+
+// SetChildren sets the children for the plan.
+func (p *BatchPointGetPlan) SetChildren(...PhysicalPlan) {}
+
+// SetChild sets a specific child for the plan.
+func (p *BatchPointGetPlan) SetChild(i int, child PhysicalPlan) {}
+
+// ResolveIndices resolves the indices for columns. After doing this, the columns can evaluate the rows by their indices.
+func (p *BatchPointGetPlan) ResolveIndices() error {
+	return nil
+}
+
 // TryFastPlan tries to use the PointGetPlan for the query.
 func TryFastPlan(ctx sessionctx.Context, node ast.Node) Plan {
 	switch x := node.(type) {
@@ -146,7 +281,7 @@ func TryFastPlan(ctx sessionctx.Context, node ast.Node) Plan {
 		if fp := tryWhereIn2BatchPointGet(ctx, x); fp != nil {
 			return fp
 		}
-		fp := tryPointGetPlan(ctx, x)
+		fp := tryCachedPointGetPlan(ctx, x.Text(), x)
 		if fp != nil {
 			if checkFastPlanPrivilege(ctx, fp, mysql.SelectPriv) != nil {
 				return nil
@@ -177,6 +312,12 @@ func TryFastPlan(ctx sessionctx.Context, node ast.Node) Plan {
 	return nil
 }
 
+// tryWhereIn2BatchPointGet plans one PointGetPlan per IN-list entry, drops
+// the entries that match no row, and wraps the rest in a PhysicalUnionAll
+// (a PhysicalTableDual when none are left). Entries may resolve to different
+// partitions of a partitioned table. Merging the entries into a single
+// BatchPointGetPlan is skipped while batchPointGetExecSupported is false, so
+// this never returns a BatchPointGetPlan.
 func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) Plan {
 	if selStmt.OrderBy != nil || selStmt.GroupBy != nil || selStmt.Limit != nil ||
 		selStmt.Having != nil || len(selStmt.WindowSpecs) > 0 ||
@@ -188,8 +329,7 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) P
 		return nil
 	}
 
-	children := make([]PhysicalPlan, 0, len(in.List))
-	chReqProps := make([]*property.PhysicalProperty, 0, len(in.List))
+	children := make([]*PointGetPlan, 0, len(in.List))
 	reusedStmt := &ast.SelectStmt{
 		SelectStmtOpts: selStmt.SelectStmtOpts,
 		Distinct:       selStmt.Distinct,
@@ -199,12 +339,6 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) P
 
 	switch leftExpr := in.Expr.(type) {
 	case *ast.ColumnNameExpr:
-		reusedStmt := &ast.SelectStmt{
-			SelectStmtOpts: selStmt.SelectStmtOpts,
-			Distinct:       selStmt.Distinct,
-			From:           selStmt.From,
-			Fields:         selStmt.Fields,
-		}
 		for _, row := range in.List {
 			where := &ast.BinaryOperationExpr{
 				Op: opcode.EQ,
@@ -212,12 +346,18 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) P
 				R:  row,
 			}
 			reusedStmt.Where = where
-			fp := TryFastPlan(ctx, reusedStmt)
+			fp := tryPointGetPlan(ctx, reusedStmt)
 			if fp == nil {
 				return nil
 			}
-			chReqProps = append(chReqProps, &property.PhysicalProperty{ExpectedCnt: 1})
-			children = append(children, fp.(*PointGetPlan))
+			if checkFastPlanPrivilege(ctx, fp, mysql.SelectPriv) != nil {
+				return nil
+			}
+			if fp.IsTableDual {
+				// This entry matches no row; it contributes nothing to the result.
+				continue
+			}
+			children = append(children, fp)
 		}
 
 	case *ast.RowExpr:
@@ -249,26 +389,317 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) P
 				}
 			}
 			reusedStmt.Where = where
-			fp := TryFastPlan(ctx, reusedStmt)
+			fp := tryPointGetPlan(ctx, reusedStmt)
 			if fp == nil {
 				return nil
 			}
-			chReqProps = append(chReqProps, &property.PhysicalProperty{ExpectedCnt: 1})
-			children = append(children, fp.(*PointGetPlan))
+			if checkFastPlanPrivilege(ctx, fp, mysql.SelectPriv) != nil {
+				return nil
+			}
+			if fp.IsTableDual {
+				continue
+			}
+			children = append(children, fp)
 		}
 
 	default:
 		return nil
 	}
 
+	if len(children) == 0 {
+		tblName, tblAlias := getSingleTableNameAndAlias(selStmt.From)
+		schema := buildSchemaFromFields(ctx, tblName.Schema, tblName.TableInfo, tblAlias, selStmt.Fields.Fields)
+		tableDual := PhysicalTableDual{}
+		tableDual.SetSchema(schema)
+		return tableDual.Init(ctx, &property.StatsInfo{})
+	}
+
+	// batchPointGetExecSupported is deliberately false: BatchPointGetPlan has
+	// no attach2Task/ToPB implementation yet, and nothing in the executor
+	// package builds a BatchGet RPC executor from it. Routing real queries
+	// into it here would plan ordinary `... WHERE pk IN (...)` queries into a
+	// dead end with no execution path. tryBuildBatchPointGetPlan is kept so
+	// the executor-side change can flip this flag on without re-deriving the
+	// merge-eligibility logic.
+	if batchPointGetExecSupported {
+		if bp := tryBuildBatchPointGetPlan(ctx, children); bp != nil {
+			return bp
+		}
+	}
+
+	chReqProps := make([]*property.PhysicalProperty, len(children))
+	physChildren := make([]PhysicalPlan, len(children))
+	for i, c := range children {
+		chReqProps[i] = &property.PhysicalProperty{ExpectedCnt: 1}
+		physChildren[i] = c
+	}
 	ua := PhysicalUnionAll{
 		IsPointGetUnion: true,
 	}.Init(ctx, children[0].statsInfo().Scale(float64(len(children))), chReqProps...)
 	ua.SetSchema(children[0].Schema())
-	ua.SetChildren(children...)
+	ua.SetChildren(physChildren...)
 	return ua
 }
 
+// tryBuildBatchPointGetPlan merges homogeneous PointGetPlans - ones hitting
+// the same handle column or the same unique index of the same physical table
+// - into a single BatchPointGetPlan. It returns nil when the plans are not
+// homogeneous, leaving the caller to fall back to the PhysicalUnionAll path.
+func tryBuildBatchPointGetPlan(ctx sessionctx.Context, children []*PointGetPlan) *BatchPointGetPlan {
+	first := children[0]
+	for _, c := range children[1:] {
+		if c.TblInfo.ID != first.TblInfo.ID {
+			return nil
+		}
+		if (c.PartitionInfo == nil) != (first.PartitionInfo == nil) {
+			return nil
+		}
+		if c.PartitionInfo != nil && c.PartitionInfo.ID != first.PartitionInfo.ID {
+			return nil
+		}
+		if (c.IndexInfo == nil) != (first.IndexInfo == nil) {
+			return nil
+		}
+		if c.IndexInfo != nil && c.IndexInfo.ID != first.IndexInfo.ID {
+			return nil
+		}
+		if c.IndexOnly != first.IndexOnly {
+			return nil
+		}
+	}
+
+	p := &BatchPointGetPlan{
+		basePlan:      newBasePlan(ctx, "Batch_Point_Get"),
+		schema:        first.Schema(),
+		TblInfo:       first.TblInfo,
+		IndexInfo:     first.IndexInfo,
+		PartitionInfo: first.PartitionInfo,
+		IndexOnly:     first.IndexOnly,
+	}
+	ctx.GetSessionVars().StmtCtx.Tables = []stmtctx.TableEntry{{DB: ctx.GetSessionVars().CurrentDB, Table: first.TblInfo.Name.L}}
+
+	if first.IndexInfo == nil {
+		p.Handles = make([]int64, 0, len(children))
+		p.HandleParams = make([]*driver.ParamMarkerExpr, 0, len(children))
+		p.UnsignedHandle = first.UnsignedHandle
+		for _, c := range children {
+			p.Handles = append(p.Handles, c.Handle)
+			p.HandleParams = append(p.HandleParams, c.HandleParam)
+		}
+	} else {
+		p.IndexValues = make([][]types.Datum, 0, len(children))
+		p.IndexValueParams = make([][]*driver.ParamMarkerExpr, 0, len(children))
+		for _, c := range children {
+			p.IndexValues = append(p.IndexValues, c.IndexValues)
+			p.IndexValueParams = append(p.IndexValueParams, c.IndexValueParams)
+		}
+	}
+	return p
+}
+
+// pointGetPlanCacheCapacity bounds the number of skeletons kept per session,
+// so a session that runs many distinct ad-hoc point queries cannot grow the
+// cache without bound; the least-recently-used entry is evicted once the
+// capacity is exceeded.
+const pointGetPlanCacheCapacity = 100
+
+// pointGetPlanCacheKey identifies a cache entry by the original statement's
+// text (the prepared statement's SQL, or an ad-hoc statement's own SQL), the
+// schema version it was planned against, and the ID of the table it resolved
+// to. The table ID matters on its own: two sessions (or the same session
+// after a `USE`) can run textually identical SQL against same-named tables in
+// different databases without any schema version change in between, and
+// those must never share a cache entry. Re-executions of the same prepared
+// statement share this key, while each synthesized `SelectStmt` built for an
+// UPDATE/DELETE rewrite resolves to the key of the real outer statement it
+// came from, rather than its own throwaway AST identity. The per-entry
+// statements of the IN-list rewrite are not cached at all (see
+// tryPointGetPlan).
+type pointGetPlanCacheKey struct {
+	text          string
+	schemaVersion int64
+	tableID       int64
+}
+
+// pointGetPlanCache is a small fixed-capacity LRU keyed by
+// pointGetPlanCacheKey. It is stored once per session via
+// sessionctx.Context.SetValue/Value, so it is naturally released when the
+// session ends.
+type pointGetPlanCache struct {
+	ll    *list.List
+	items map[pointGetPlanCacheKey]*list.Element
+}
+
+type pointGetPlanCacheElem struct {
+	key  pointGetPlanCacheKey
+	plan *PointGetPlan
+}
+
+func newPointGetPlanCache() *pointGetPlanCache {
+	return &pointGetPlanCache{ll: list.New(), items: make(map[pointGetPlanCacheKey]*list.Element)}
+}
+
+func (c *pointGetPlanCache) get(key pointGetPlanCacheKey) *PointGetPlan {
+	e, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*pointGetPlanCacheElem).plan
+}
+
+func (c *pointGetPlanCache) put(key pointGetPlanCacheKey, plan *PointGetPlan) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*pointGetPlanCacheElem).plan = plan
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&pointGetPlanCacheElem{key: key, plan: plan})
+	if c.ll.Len() > pointGetPlanCacheCapacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*pointGetPlanCacheElem).key)
+	}
+}
+
+// pointGetPlanCacheCtxKey is the fmt.Stringer key the cache is stored under
+// in the session's generic value bag (sessionctx.Context.SetValue/Value).
+type pointGetPlanCacheCtxKey struct{}
+
+func (pointGetPlanCacheCtxKey) String() string { return "point_get_plan_cache" }
+
+func getPointGetPlanCache(ctx sessionctx.Context) *pointGetPlanCache {
+	if v := ctx.Value(pointGetPlanCacheCtxKey{}); v != nil {
+		return v.(*pointGetPlanCache)
+	}
+	cache := newPointGetPlanCache()
+	ctx.SetValue(pointGetPlanCacheCtxKey{}, cache)
+	return cache
+}
+
+// tryCachedPointGetPlan is the entry point for call sites that may see the
+// same statement text executed repeatedly (a prepared statement's repeated
+// EXECUTE, in particular): cacheKeyText should be the Text() of the
+// statement the caller is really planning on behalf of - the original SELECT,
+// UPDATE, or DELETE - not of a synthesized helper SelectStmt, so that
+// repeated executions hit the same key regardless of how many intermediate
+// AST nodes get rebuilt along the way. When SessionVars.DisablePointGetPlanCache
+// is true, every call goes straight to buildPointGetPlan. This package only
+// reads that field; it registers no system variable that sets it.
+func tryCachedPointGetPlan(ctx sessionctx.Context, cacheKeyText string, selStmt *ast.SelectStmt) *PointGetPlan {
+	tblName, _ := getSingleTableNameAndAlias(selStmt.From)
+	if tblName == nil || tblName.TableInfo == nil {
+		return buildPointGetPlan(ctx, selStmt)
+	}
+	if ctx.GetSessionVars().DisablePointGetPlanCache {
+		return buildPointGetPlan(ctx, selStmt)
+	}
+	// tableID is resolved from the live selStmt, not from anything cached, so
+	// two statements with identical text and schema version that resolve to
+	// different physical tables (e.g. same table name in different databases)
+	// never collide on the same key.
+	key := pointGetPlanCacheKey{
+		text:          cacheKeyText,
+		schemaVersion: ctx.GetSessionVars().TxnCtx.SchemaVersion,
+		tableID:       tblName.TableInfo.ID,
+	}
+	cache := getPointGetPlanCache(ctx)
+	if skeleton := cache.get(key); skeleton != nil {
+		if p := reuseCachedPointGetPlan(ctx, skeleton, selStmt); p != nil {
+			return p
+		}
+	}
+	p := buildPointGetPlan(ctx, selStmt)
+	if p != nil && !p.IsTableDual {
+		cache.put(key, p)
+	}
+	return p
+}
+
+// reuseCachedPointGetPlan rebuilds a PointGetPlan from a cached skeleton,
+// re-evaluating only the parameter datums found in selStmt.Where today: the
+// overflow/truncation checks on the handle value and the partition pruning
+// are redone from scratch (preserving IsTableDual semantics on overflow or
+// out-of-range partition values), and index values are taken as they are,
+// as buildPointGetPlan does, so a changed parameter can never reuse a stale
+// result.
+// It returns nil if the skeleton no longer applies, in which case the caller
+// should fall back to a full rebuild.
+//
+// The table identity is re-derived from selStmt, not trusted from the
+// skeleton: the cache key already includes the resolved table ID, but this
+// is the last line of defense against ever reading skeleton.TblInfo (and the
+// IndexInfo/handle column hanging off it) for a table other than the one
+// selStmt actually names right now.
+func reuseCachedPointGetPlan(ctx sessionctx.Context, skeleton *PointGetPlan, selStmt *ast.SelectStmt) *PointGetPlan {
+	tblName, tblAlias := getSingleTableNameAndAlias(selStmt.From)
+	if tblName == nil || tblName.TableInfo == nil || tblName.TableInfo.ID != skeleton.TblInfo.ID {
+		return nil
+	}
+	pairs := make([]nameValuePair, 0, 4)
+	pairs = getNameValuePairs(pairs, tblAlias, selStmt.Where)
+	if pairs == nil {
+		return nil
+	}
+	tbl := skeleton.TblInfo
+
+	var partitionDef *model.PartitionDefinition
+	if tbl.GetPartitionInfo() != nil {
+		var prunable bool
+		partitionDef, prunable = getPrunedPartitionDef(ctx, tbl, pairs)
+		if !prunable {
+			return nil
+		}
+		if partitionDef == nil {
+			p := newPointGetPlan(ctx, skeleton.schema, skeleton.DBName, tbl)
+			p.IsTableDual = true
+			return p
+		}
+	}
+
+	if skeleton.IndexInfo == nil {
+		handlePair, fieldType := findPKHandle(tbl, pairs)
+		if handlePair.value.Kind() == types.KindNull || len(pairs) != 1 {
+			return nil
+		}
+		p := newPointGetPlan(ctx, skeleton.schema, skeleton.DBName, tbl)
+		p.PartitionInfo = partitionDef
+		intDatum, err := handlePair.value.ConvertTo(ctx.GetSessionVars().StmtCtx, fieldType)
+		if err != nil {
+			if terror.ErrorEqual(types.ErrOverflow, err) {
+				p.IsTableDual = true
+				return p
+			}
+			if !terror.ErrorEqual(types.ErrTruncatedWrongVal, err) {
+				return nil
+			}
+		}
+		cmp, err := intDatum.CompareDatum(ctx.GetSessionVars().StmtCtx, &handlePair.value)
+		if err != nil {
+			return nil
+		} else if cmp != 0 {
+			p.IsTableDual = true
+			return p
+		}
+		p.Handle = intDatum.GetInt64()
+		p.UnsignedHandle = mysql.HasUnsignedFlag(fieldType.Flag)
+		p.HandleParam = handlePair.param
+		return p
+	}
+
+	idxValues, idxValueParams := getIndexValues(skeleton.IndexInfo, pairs)
+	if idxValues == nil {
+		return nil
+	}
+	p := newPointGetPlan(ctx, skeleton.schema, skeleton.DBName, tbl)
+	p.IndexInfo = skeleton.IndexInfo
+	p.IndexValues = idxValues
+	p.IndexValueParams = idxValueParams
+	p.PartitionInfo = partitionDef
+	p.IndexOnly = skeleton.IndexOnly
+	return p
+}
+
 // tryPointGetPlan determine if the SelectStmt can use a PointGetPlan.
 // Returns nil if not applicable.
 // To use the PointGetPlan the following rules must be satisfied:
@@ -276,7 +707,18 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) P
 // 2. It must be a single table select.
 // 3. All the columns must be public and generated.
 // 4. The condition is an access path that the range is a unique key.
+//
+// This always builds a fresh plan; it is used for the IN-list rewrite in
+// tryWhereIn2BatchPointGet, where selStmt is a throwaway node rebuilt for
+// every entry and caching it would only grow the cache without ever being
+// reused. Call sites that can see the same statement executed repeatedly
+// (a prepared statement's repeated EXECUTE) should go through
+// tryCachedPointGetPlan instead.
 func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetPlan {
+	return buildPointGetPlan(ctx, selStmt)
+}
+
+func buildPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetPlan {
 	if selStmt.Having != nil {
 		return nil
 	} else if selStmt.Limit != nil {
@@ -297,13 +739,6 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetP
 	if dbName.L == "" {
 		dbName = model.NewCIStr(ctx.GetSessionVars().CurrentDB)
 	}
-	// Do not handle partitioned table.
-	// Table partition implementation translates LogicalPlan from `DataSource` to
-	// `Union -> DataSource` in the logical plan optimization pass, since PointGetPlan
-	// bypass the logical plan optimization, it can't support partitioned table.
-	if tbl.GetPartitionInfo() != nil {
-		return nil
-	}
 	for _, col := range tbl.Columns {
 		// Do not handle generated columns.
 		if col.IsGenerated() {
@@ -319,6 +754,48 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetP
 	if pairs == nil {
 		return nil
 	}
+	// A unique index permits multiple rows with NULL in the indexed column
+	// (MySQL/InnoDB never treats NULL as equal to NULL for uniqueness), so an
+	// `IS NULL` predicate can legitimately match any number of rows and is not
+	// a point-get predicate in general. The only case that is still safe to
+	// resolve here is a column declared NOT NULL, where the predicate provably
+	// matches no row at all; anything else bails out to the full optimizer,
+	// which can run the necessary scan. `IS NULL` on a nullable unique-indexed
+	// column is deliberately left unsupported by the fast path for that
+	// reason, not an oversight - it still goes through the normal optimizer.
+	switch dual, ok := checkNullPredicate(tbl, pairs); {
+	case !ok:
+		return nil
+	case dual:
+		schema := buildSchemaFromFields(ctx, tblName.Schema, tbl, tblAlias, selStmt.Fields.Fields)
+		if schema == nil {
+			return nil
+		}
+		p := newPointGetPlan(ctx, schema, dbName, tbl)
+		p.IsTableDual = true
+		return p
+	}
+	// For a partitioned table, only use the fast path when the partition
+	// expression can be evaluated from the known equal-value pairs. Otherwise
+	// fall back to the full optimizer, which already knows how to prune
+	// partitions via the `Union -> DataSource` rewrite.
+	var partitionDef *model.PartitionDefinition
+	if tbl.GetPartitionInfo() != nil {
+		var prunable bool
+		partitionDef, prunable = getPrunedPartitionDef(ctx, tbl, pairs)
+		if !prunable {
+			return nil
+		}
+		if partitionDef == nil {
+			schema := buildSchemaFromFields(ctx, tblName.Schema, tbl, tblAlias, selStmt.Fields.Fields)
+			if schema == nil {
+				return nil
+			}
+			p := newPointGetPlan(ctx, schema, dbName, tbl)
+			p.IsTableDual = true
+			return p
+		}
+	}
 	handlePair, fieldType := findPKHandle(tbl, pairs)
 	if handlePair.value.Kind() != types.KindNull && len(pairs) == 1 {
 		schema := buildSchemaFromFields(ctx, tblName.Schema, tbl, tblAlias, selStmt.Fields.Fields)
@@ -326,6 +803,7 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetP
 			return nil
 		}
 		p := newPointGetPlan(ctx, schema, dbName, tbl)
+		p.PartitionInfo = partitionDef
 		intDatum, err := handlePair.value.ConvertTo(ctx.GetSessionVars().StmtCtx, fieldType)
 		if err != nil {
 			if terror.ErrorEqual(types.ErrOverflow, err) {
@@ -369,6 +847,8 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt) *PointGetP
 		p.IndexInfo = idxInfo
 		p.IndexValues = idxValues
 		p.IndexValueParams = idxValueParams
+		p.PartitionInfo = partitionDef
+		p.IndexOnly = isCoveringIndex(schema, idxInfo)
 		return p
 	}
 	return nil
@@ -465,8 +945,27 @@ func getSingleTableNameAndAlias(tableRefs *ast.TableRefsClause) (tblName *ast.Ta
 	return tblName, tblAlias
 }
 
-// getNameValuePairs extracts `column = constant/paramMarker` conditions from expr as name value pairs.
+// getNameValuePairs extracts `column = constant/paramMarker` and
+// `column IS NULL` conditions from expr as name value pairs. An `IS NULL`
+// pair carries a NULL-kind Datum; whether that's usable as a point-get
+// predicate depends on the column's nullability, which checkNullPredicate
+// decides once the pairs are matched against a concrete table/index (a
+// unique index does not prevent multiple rows from having a NULL value, so
+// `IS NULL` is only a true point-get predicate when the column is NOT NULL).
 func getNameValuePairs(nvPairs []nameValuePair, tblName model.CIStr, expr ast.ExprNode) []nameValuePair {
+	if isNull, ok := expr.(*ast.IsNullExpr); ok {
+		if isNull.Not {
+			return nil
+		}
+		colName, ok := isNull.Expr.(*ast.ColumnNameExpr)
+		if !ok {
+			return nil
+		}
+		if colName.Name.Table.L != "" && colName.Name.Table.L != tblName.L {
+			return nil
+		}
+		return append(nvPairs, nameValuePair{colName: colName.Name.Name.L})
+	}
 	binOp, ok := expr.(*ast.BinaryOperationExpr)
 	if !ok {
 		return nil
@@ -516,6 +1015,78 @@ func getNameValuePairs(nvPairs []nameValuePair, tblName model.CIStr, expr ast.Ex
 	return nil
 }
 
+// getPrunedPartitionDef evaluates the partition expression of tbl against the
+// known equal-value pairs and tries to resolve it to a single partition.
+// prunable is false when the partition expression references a column that
+// is not present in pairs, or when the partition type is not one of
+// HASH/RANGE/LIST; in that case the caller should give up on the fast path.
+// When prunable is true, a nil def means the value falls outside every
+// partition, i.e. the query matches no row.
+func getPrunedPartitionDef(ctx sessionctx.Context, tbl *model.TableInfo, pairs []nameValuePair) (def *model.PartitionDefinition, prunable bool) {
+	pi := tbl.GetPartitionInfo()
+	switch pi.Type {
+	case model.PartitionTypeHash, model.PartitionTypeRange, model.PartitionTypeList:
+	default:
+		return nil, false
+	}
+	expr, err := expression.ParseSimpleExprWithTableInfo(ctx, pi.Expr, tbl)
+	if err != nil {
+		return nil, false
+	}
+	for _, col := range expression.ExtractColumns(expr) {
+		if findInPairs(col.ColName.L, pairs) == -1 {
+			return nil, false
+		}
+	}
+	datums := make([]types.Datum, len(tbl.Columns))
+	for i, col := range tbl.Columns {
+		if j := findInPairs(col.Name.L, pairs); j != -1 {
+			datums[i] = pairs[j].value
+		}
+	}
+	val, isNull, err := expr.EvalInt(ctx, chunk.MutRowFromDatums(datums).ToRow())
+	if err != nil || isNull {
+		return nil, false
+	}
+	switch pi.Type {
+	case model.PartitionTypeHash:
+		idx := val % int64(len(pi.Definitions))
+		if idx < 0 {
+			idx += int64(len(pi.Definitions))
+		}
+		return &pi.Definitions[idx], true
+	case model.PartitionTypeRange:
+		for i := range pi.Definitions {
+			if len(pi.Definitions[i].LessThan) != 1 {
+				return nil, false
+			}
+			lessThan, err := strconv.ParseInt(pi.Definitions[i].LessThan[0], 10, 64)
+			if err != nil {
+				// MAXVALUE or an expression we don't evaluate here; give up pruning.
+				return nil, false
+			}
+			if val < lessThan {
+				return &pi.Definitions[i], true
+			}
+		}
+		return nil, true
+	case model.PartitionTypeList:
+		for i := range pi.Definitions {
+			for _, inValue := range pi.Definitions[i].InValues {
+				if len(inValue) != 1 {
+					continue
+				}
+				n, err := strconv.ParseInt(inValue[0], 10, 64)
+				if err == nil && n == val {
+					return &pi.Definitions[i], true
+				}
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
 func findPKHandle(tblInfo *model.TableInfo, pairs []nameValuePair) (handlePair nameValuePair, fieldType *types.FieldType) {
 	if !tblInfo.PKIsHandle {
 		return handlePair, nil
@@ -532,6 +1103,49 @@ func findPKHandle(tblInfo *model.TableInfo, pairs []nameValuePair) (handlePair n
 	return handlePair, nil
 }
 
+// findColByName looks up a column of tbl by its lower-cased name, mirroring
+// findCol but for the plain string names nameValuePair carries.
+func findColByName(tbl *model.TableInfo, name string) *model.ColumnInfo {
+	for _, col := range tbl.Columns {
+		if col.Name.L == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// checkNullPredicate inspects pairs for any `IS NULL` predicate (a NULL-kind
+// Datum) and decides whether the fast path can still handle it. A unique
+// index does not prevent multiple rows from having a NULL value in the
+// indexed column (MySQL/InnoDB never treats NULL as equal to NULL for
+// uniqueness), so `IS NULL` is only safe to resolve here when the column is
+// declared NOT NULL, in which case the predicate provably matches no row and
+// dual is true. If a NULL predicate targets a nullable column, ok is false
+// and the caller must bail out to the full optimizer, which can run the
+// necessary scan.
+func checkNullPredicate(tbl *model.TableInfo, pairs []nameValuePair) (dual bool, ok bool) {
+	for _, pair := range pairs {
+		if !pair.value.IsNull() {
+			continue
+		}
+		col := findColByName(tbl, pair.colName)
+		if col == nil {
+			return false, false
+		}
+		if mysql.HasNotNullFlag(col.Flag) {
+			dual = true
+			continue
+		}
+		return false, false
+	}
+	return dual, true
+}
+
+// getIndexValues builds the per-column values for idxInfo from pairs, in
+// index column order. By the time this is called, checkNullPredicate has
+// already rejected any `IS NULL` predicate against a nullable column, so a
+// NULL-kind Datum reaching here always belongs to a NOT NULL column and is
+// passed through like any other value.
 func getIndexValues(idxInfo *model.IndexInfo, pairs []nameValuePair) ([]types.Datum, []*driver.ParamMarkerExpr) {
 	idxValues := make([]types.Datum, 0, 4)
 	idxValueParams := make([]*driver.ParamMarkerExpr, 0, 4)
@@ -555,6 +1169,30 @@ func getIndexValues(idxInfo *model.IndexInfo, pairs []nameValuePair) ([]types.Da
 	return nil, nil
 }
 
+// isCoveringIndex reports whether every column in schema can be decoded
+// straight from an index key/value built from idxInfo, with no second fetch
+// of the table row. A prefix index column (e.g. `KEY (email(10))`) only
+// stores the first idxCol.Length bytes of the column, so it only counts as
+// covering when it indexes the full column - idxCol.Length must be
+// types.UnspecifiedLength (no prefix) or already equal to the column's
+// declared length.
+func isCoveringIndex(schema *expression.Schema, idxInfo *model.IndexInfo) bool {
+	idxCols := make(map[string]int, len(idxInfo.Columns))
+	for _, idxCol := range idxInfo.Columns {
+		idxCols[idxCol.Name.L] = idxCol.Length
+	}
+	for _, col := range schema.Columns {
+		length, ok := idxCols[col.ColName.L]
+		if !ok {
+			return false
+		}
+		if length != types.UnspecifiedLength && length != col.RetType.Flen {
+			return false
+		}
+	}
+	return true
+}
+
 func findInPairs(colName string, pairs []nameValuePair) int {
 	for i, pair := range pairs {
 		if pair.colName == colName {
@@ -572,7 +1210,7 @@ func tryUpdatePointPlan(ctx sessionctx.Context, updateStmt *ast.UpdateStmt) Plan
 		OrderBy: updateStmt.Order,
 		Limit:   updateStmt.Limit,
 	}
-	fastSelect := tryPointGetPlan(ctx, selStmt)
+	fastSelect := tryCachedPointGetPlan(ctx, updateStmt.Text(), selStmt)
 	if fastSelect == nil {
 		return nil
 	}
@@ -643,7 +1281,7 @@ func tryDeletePointPlan(ctx sessionctx.Context, delStmt *ast.DeleteStmt) Plan {
 		OrderBy: delStmt.Order,
 		Limit:   delStmt.Limit,
 	}
-	fastSelect := tryPointGetPlan(ctx, selStmt)
+	fastSelect := tryCachedPointGetPlan(ctx, delStmt.Text(), selStmt)
 	if fastSelect == nil {
 		return nil
 	}
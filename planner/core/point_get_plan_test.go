@@ -0,0 +1,387 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/parser_driver"
+	"github.com/pingcap/tidb/util/mock"
+)
+
+func newIntColumn(name string, offset int) *model.ColumnInfo {
+	col := &model.ColumnInfo{
+		Name:   model.NewCIStr(name),
+		Offset: offset,
+		State:  model.StatePublic,
+	}
+	col.FieldType = *types.NewFieldType(mysql.TypeLonglong)
+	return col
+}
+
+func newHashPartitionedTable() *model.TableInfo {
+	tbl := &model.TableInfo{
+		Name: model.NewCIStr("t"),
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+		Partition: &model.PartitionInfo{
+			Type: model.PartitionTypeHash,
+			Expr: "id",
+			Definitions: []model.PartitionDefinition{
+				{ID: 1, Name: model.NewCIStr("p0")},
+				{ID: 2, Name: model.NewCIStr("p1")},
+				{ID: 3, Name: model.NewCIStr("p2")},
+				{ID: 4, Name: model.NewCIStr("p3")},
+			},
+		},
+	}
+	return tbl
+}
+
+func TestGetPrunedPartitionDefHash(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newHashPartitionedTable()
+	pairs := []nameValuePair{{colName: "id", value: types.NewIntDatum(5)}}
+
+	def, prunable := getPrunedPartitionDef(ctx, tbl, pairs)
+	if !prunable {
+		t.Fatalf("expected a hash-partitioned table with all partition columns bound to prune")
+	}
+	want := &tbl.Partition.Definitions[5%4]
+	if def != want {
+		t.Fatalf("got partition %+v, want %+v", def, want)
+	}
+}
+
+func TestGetPrunedPartitionDefHashNotPrunable(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newHashPartitionedTable()
+	// No pairs bind the partitioning column, so pruning must not happen.
+	_, prunable := getPrunedPartitionDef(ctx, tbl, nil)
+	if prunable {
+		t.Fatalf("expected pruning to fail when the partitioning column is unbound")
+	}
+}
+
+func newRangePartitionedTable() *model.TableInfo {
+	return &model.TableInfo{
+		Name: model.NewCIStr("t"),
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+		Partition: &model.PartitionInfo{
+			Type: model.PartitionTypeRange,
+			Expr: "id",
+			Definitions: []model.PartitionDefinition{
+				{ID: 1, Name: model.NewCIStr("p0"), LessThan: []string{"100"}},
+				{ID: 2, Name: model.NewCIStr("p1"), LessThan: []string{"200"}},
+			},
+		},
+	}
+}
+
+func TestGetPrunedPartitionDefRange(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newRangePartitionedTable()
+	pairs := []nameValuePair{{colName: "id", value: types.NewIntDatum(150)}}
+
+	def, prunable := getPrunedPartitionDef(ctx, tbl, pairs)
+	if !prunable {
+		t.Fatalf("expected a range-partitioned table with a bound column to prune")
+	}
+	if def != &tbl.Partition.Definitions[1] {
+		t.Fatalf("got partition %+v, want p1", def)
+	}
+}
+
+func TestGetPrunedPartitionDefRangeOutOfRange(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newRangePartitionedTable()
+	pairs := []nameValuePair{{colName: "id", value: types.NewIntDatum(500)}}
+
+	def, prunable := getPrunedPartitionDef(ctx, tbl, pairs)
+	if !prunable {
+		t.Fatalf("a value past every LessThan bound should still be decided (no matching partition)")
+	}
+	if def != nil {
+		t.Fatalf("got partition %+v, want nil for a value with no matching partition", def)
+	}
+}
+
+func newListPartitionedTable() *model.TableInfo {
+	return &model.TableInfo{
+		Name: model.NewCIStr("t"),
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+		Partition: &model.PartitionInfo{
+			Type: model.PartitionTypeList,
+			Expr: "id",
+			Definitions: []model.PartitionDefinition{
+				{ID: 1, Name: model.NewCIStr("p0"), InValues: [][]string{{"1"}, {"2"}}},
+				{ID: 2, Name: model.NewCIStr("p1"), InValues: [][]string{{"3"}, {"4"}}},
+			},
+		},
+	}
+}
+
+func TestGetPrunedPartitionDefList(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newListPartitionedTable()
+	pairs := []nameValuePair{{colName: "id", value: types.NewIntDatum(3)}}
+
+	def, prunable := getPrunedPartitionDef(ctx, tbl, pairs)
+	if !prunable {
+		t.Fatalf("expected a list-partitioned table with a bound column to prune")
+	}
+	if def != &tbl.Partition.Definitions[1] {
+		t.Fatalf("got partition %+v, want p1", def)
+	}
+}
+
+func TestCheckNullPredicateNotNullColumnIsDual(t *testing.T) {
+	tbl := &model.TableInfo{
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+	}
+	tbl.Columns[0].Flag |= mysql.NotNullFlag
+	pairs := []nameValuePair{{colName: "id", value: types.Datum{}}}
+
+	dual, ok := checkNullPredicate(tbl, pairs)
+	if !ok {
+		t.Fatalf("a NOT NULL column should still be resolvable")
+	}
+	if !dual {
+		t.Fatalf("`id IS NULL` against a NOT NULL column should be table-dual")
+	}
+}
+
+func TestCheckNullPredicateNullableColumnBailsOut(t *testing.T) {
+	tbl := &model.TableInfo{
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+	}
+	pairs := []nameValuePair{{colName: "id", value: types.Datum{}}}
+
+	_, ok := checkNullPredicate(tbl, pairs)
+	if ok {
+		t.Fatalf("`IS NULL` against a nullable unique-indexed column can match many rows and must bail out")
+	}
+}
+
+func newHandlePointGet(ctx sessionctx.Context, tbl *model.TableInfo, handle int64) *PointGetPlan {
+	p := newPointGetPlan(ctx, expression.NewSchema(), model.NewCIStr("test"), tbl)
+	p.Handle = handle
+	return p
+}
+
+func TestTryBuildBatchPointGetPlanMergesSameTable(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := &model.TableInfo{ID: 1, Name: model.NewCIStr("t")}
+	children := []*PointGetPlan{
+		newHandlePointGet(ctx, tbl, 1),
+		newHandlePointGet(ctx, tbl, 2),
+	}
+
+	bp := tryBuildBatchPointGetPlan(ctx, children)
+	if bp == nil {
+		t.Fatalf("expected homogeneous handle point-gets on the same table to merge")
+	}
+	if len(bp.Handles) != 2 || bp.Handles[0] != 1 || bp.Handles[1] != 2 {
+		t.Fatalf("got handles %v, want [1 2]", bp.Handles)
+	}
+}
+
+func TestTryBuildBatchPointGetPlanRejectsDifferentTables(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl1 := &model.TableInfo{ID: 1, Name: model.NewCIStr("t1")}
+	tbl2 := &model.TableInfo{ID: 2, Name: model.NewCIStr("t2")}
+	children := []*PointGetPlan{
+		newHandlePointGet(ctx, tbl1, 1),
+		newHandlePointGet(ctx, tbl2, 2),
+	}
+
+	if bp := tryBuildBatchPointGetPlan(ctx, children); bp != nil {
+		t.Fatalf("expected point-gets against different tables not to merge, got %+v", bp)
+	}
+}
+
+func TestCheckNullPredicateNoNullPairs(t *testing.T) {
+	tbl := &model.TableInfo{
+		Columns: []*model.ColumnInfo{
+			newIntColumn("id", 0),
+		},
+	}
+	pairs := []nameValuePair{{colName: "id", value: types.NewIntDatum(1)}}
+
+	dual, ok := checkNullPredicate(tbl, pairs)
+	if !ok || dual {
+		t.Fatalf("an ordinary equality pair should pass through as non-dual, got dual=%v ok=%v", dual, ok)
+	}
+}
+
+func newVarcharColumn(name string, flen int) *expression.Column {
+	ft := types.NewFieldType(mysql.TypeVarchar)
+	ft.Flen = flen
+	return &expression.Column{ColName: model.NewCIStr(name), RetType: ft}
+}
+
+func TestIsCoveringIndexFullColumn(t *testing.T) {
+	schema := expression.NewSchema(newVarcharColumn("email", 64))
+	idxInfo := &model.IndexInfo{
+		Columns: []*model.IndexColumn{
+			{Name: model.NewCIStr("email"), Length: types.UnspecifiedLength},
+		},
+	}
+
+	if !isCoveringIndex(schema, idxInfo) {
+		t.Fatalf("an index on the whole column should cover a schema selecting only that column")
+	}
+}
+
+func TestIsCoveringIndexPrefixNotCovering(t *testing.T) {
+	schema := expression.NewSchema(newVarcharColumn("email", 64))
+	idxInfo := &model.IndexInfo{
+		Columns: []*model.IndexColumn{
+			{Name: model.NewCIStr("email"), Length: 10},
+		},
+	}
+
+	if isCoveringIndex(schema, idxInfo) {
+		t.Fatalf("a prefix index `email(10)` only stores 10 bytes and must not be treated as covering the full column")
+	}
+}
+
+func TestIsCoveringIndexMissingColumn(t *testing.T) {
+	schema := expression.NewSchema(newVarcharColumn("email", 64), newVarcharColumn("name", 64))
+	idxInfo := &model.IndexInfo{
+		Columns: []*model.IndexColumn{
+			{Name: model.NewCIStr("email"), Length: types.UnspecifiedLength},
+		},
+	}
+
+	if isCoveringIndex(schema, idxInfo) {
+		t.Fatalf("a schema column absent from the index must never be treated as covered")
+	}
+}
+
+func newPKHandleTable(id int64) *model.TableInfo {
+	pk := newIntColumn("id", 0)
+	pk.Flag |= mysql.PriKeyFlag
+	return &model.TableInfo{
+		ID:         id,
+		Name:       model.NewCIStr("t"),
+		PKIsHandle: true,
+		Columns:    []*model.ColumnInfo{pk},
+	}
+}
+
+// newHandleEqSelStmt builds the minimal hand-assembled `SELECT ... FROM t
+// WHERE <col> = <val>` AST that getSingleTableNameAndAlias/getNameValuePairs
+// need, without going through the real SQL parser (not available to this
+// package's tests).
+func newHandleEqSelStmt(tbl *model.TableInfo, colName string, val int64) *ast.SelectStmt {
+	return &ast.SelectStmt{
+		From: &ast.TableRefsClause{
+			TableRefs: &ast.Join{
+				Left: &ast.TableSource{Source: &ast.TableName{Name: tbl.Name, TableInfo: tbl}},
+			},
+		},
+		Where: &ast.BinaryOperationExpr{
+			Op: opcode.EQ,
+			L:  &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: model.NewCIStr(colName)}},
+			R:  &driver.ValueExpr{Datum: types.NewIntDatum(val)},
+		},
+	}
+}
+
+func TestReuseCachedPointGetPlanReusesMatchingTable(t *testing.T) {
+	ctx := mock.NewContext()
+	tbl := newPKHandleTable(1)
+	skeleton := newPointGetPlan(ctx, expression.NewSchema(), model.NewCIStr("db1"), tbl)
+	skeleton.Handle = 1
+
+	p := reuseCachedPointGetPlan(ctx, skeleton, newHandleEqSelStmt(tbl, "id", 5))
+	if p == nil {
+		t.Fatalf("expected a matching table/handle query to reuse the skeleton")
+	}
+	if p.Handle != 5 {
+		t.Fatalf("got handle %d, want 5 (re-evaluated from the live statement, not the skeleton)", p.Handle)
+	}
+}
+
+func TestReuseCachedPointGetPlanRejectsTableIDMismatch(t *testing.T) {
+	ctx := mock.NewContext()
+	cachedTbl := newPKHandleTable(1)
+	liveTbl := newPKHandleTable(2) // same name "t", different physical table (e.g. a different database)
+
+	skeleton := newPointGetPlan(ctx, expression.NewSchema(), model.NewCIStr("db1"), cachedTbl)
+	skeleton.Handle = 1
+
+	p := reuseCachedPointGetPlan(ctx, skeleton, newHandleEqSelStmt(liveTbl, "id", 5))
+	if p != nil {
+		t.Fatalf("must not reuse a skeleton built from a different physical table, even with an identical table name")
+	}
+}
+
+func TestPointGetPlanCacheGetPut(t *testing.T) {
+	cache := newPointGetPlanCache()
+	key := pointGetPlanCacheKey{text: "select * from t where id = ?", schemaVersion: 1, tableID: 1}
+
+	if cache.get(key) != nil {
+		t.Fatalf("expected a miss on an empty cache")
+	}
+	plan := &PointGetPlan{Handle: 42}
+	cache.put(key, plan)
+	if got := cache.get(key); got != plan {
+		t.Fatalf("got %+v, want the plan just put in", got)
+	}
+}
+
+func TestPointGetPlanCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newPointGetPlanCache()
+	for i := 0; i < pointGetPlanCacheCapacity; i++ {
+		key := pointGetPlanCacheKey{text: fmt.Sprintf("stmt%d", i), tableID: int64(i)}
+		cache.put(key, &PointGetPlan{Handle: int64(i)})
+	}
+	first := pointGetPlanCacheKey{text: "stmt0", tableID: 0}
+	if cache.get(first) == nil {
+		t.Fatalf("expected stmt0 to still be cached before the cache is over capacity")
+	}
+
+	// Touching "stmt0" via get() above moved it to the front, so "stmt1" (never
+	// touched again) is now the least recently used entry and must be the one
+	// evicted when capacity is exceeded.
+	overflow := pointGetPlanCacheKey{text: "stmtN", tableID: pointGetPlanCacheCapacity}
+	cache.put(overflow, &PointGetPlan{})
+
+	if cache.get(first) == nil {
+		t.Fatalf("expected stmt0 to survive eviction since it was the most recently used")
+	}
+	lru := pointGetPlanCacheKey{text: "stmt1", tableID: 1}
+	if cache.get(lru) != nil {
+		t.Fatalf("expected stmt1 to be evicted as the least recently used entry")
+	}
+}